@@ -18,16 +18,52 @@ package main
 
 // multiple imports using import ()
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
 )
 
 // SimpleChaincode example simple Chaincode implementation
 type SimpleChaincode struct {
 }
 
+// Asset is a struct (rather than a plain string like write() uses) so we can
+// store more than one field under a single key. The `json:"..."` bits after
+// each field are struct tags - they tell json.Marshal/Unmarshal what name to
+// use for that field in the JSON, which matters if you want camelCase in
+// your JSON but capitalized (exported) field names in Go.
+// UpdatedAt is just the Unix timestamp of the last write, so a client can
+// tell which of two updates happened later.
+type Asset struct {
+	ID        string `json:"id"`
+	Owner     string `json:"owner"`
+	Type      string `json:"type"`
+	Value     int    `json:"value"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// ownerIndex is just a name we made up for our composite key index - stub.CreateCompositeKey
+// combines it with the owner and the asset id into one key (internally using a separator
+// character you won't see if you just print the string) so we can later ask the ledger
+// "give me every key you have starting with this owner" via GetStateByPartialCompositeKey.
+const ownerIndex = "owner~id"
+
+// assetKeyPrefix is prepended to every asset id before it touches PutState/GetState.
+// Without this, an asset id and an account name (or a key written by the plain write()
+// function further down) could be the exact same string and stomp on each other in the
+// world state, since under the hood they're all just keys in the same key/value store.
+const assetKeyPrefix = "asset:"
+
+// assetKey builds the real ledger key for an asset id - callers should never call
+// stub.PutState/GetState with a raw asset id, only with assetKey(id).
+func assetKey(id string) string {
+	return assetKeyPrefix + id
+}
+
 // ============================================================================================================================
 // Main
 // ============================================================================================================================
@@ -44,86 +80,154 @@ func main() {
 // Init resets all the things
 // the (t *SimpleChaincode) designates the method receiver - it will receive a pointer to a struct of type SimpleChaincode and store that as variable t
 // func name is initial capital to show it is a method on a method receiver (I think also to make it a public method that can be invoked from outside)
-// method receives 3 parameters - stub of type shim.ChaincodeStubInterface, function of type string, and args of type []string (string array)
-// method will return 2 paramters, the first of type []byte (byte array) and the second of type error
-func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+// method receives the stub of type shim.ChaincodeStubInterface and returns a pb.Response built via shim.Success/shim.Error
+func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	_, args := stub.GetFunctionAndParameters()
 	if len(args) != 1 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+		return shim.Error("Incorrect number of arguments. Expecting 1")
 	}
 	// write to the blockchain by invoking stub.PutState()
 	err := stub.PutState("hello_world", []byte(args[0]))
 	if err != nil {
-		return nil, err
+		return shim.Error(err.Error())
 	}
-	// Return null values for each of the declared return parameters
-	return nil, nil
+	// shim.Success with a nil payload signals a successful Init with nothing to return
+	return shim.Success(nil)
 }
 
 // Invoke is our entry point to invoke a chaincode function
 // the method signature is the same as Init - see there for comments on the different parts
-func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+// function and args are retrieved from the stub via GetFunctionAndParameters instead of being passed in directly
+func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
 	fmt.Println("invoke is running " + function)
 
 	// Handle different functions
 	if function == "init" { //initialize the chaincode state, used as reset
-		return t.Init(stub, "init", args)
+		return t.Init(stub)
 	} else if function == "write" {
 		// call the write() method on the t pointer
 		return t.write(stub, args)
+	} else if function == "read" { //read a variable, folded in here now that Query no longer exists
+		// call the read() method on the t pointer
+		return t.read(stub, args)
+	} else if function == "createAsset" {
+		return t.createAsset(stub, args)
+	} else if function == "updateAsset" {
+		return t.updateAsset(stub, args)
+	} else if function == "transferAsset" {
+		return t.transferAsset(stub, args)
+	} else if function == "deleteAsset" {
+		return t.deleteAsset(stub, args)
+	} else if function == "queryAsset" {
+		return t.queryAsset(stub, args)
+	} else if function == "queryAssetsByOwner" {
+		return t.queryAssetsByOwner(stub, args)
+	} else if function == "queryAssetsByOwnerIndex" {
+		return t.queryAssetsByOwnerIndex(stub, args)
+	} else if function == "createAccount" {
+		return t.createAccount(stub, args)
+	} else if function == "transfer" {
+		return t.transfer(stub, args)
+	} else if function == "balance" {
+		return t.balance(stub, args)
+	} else if function == "getHistory" {
+		return t.getHistory(stub, args)
+	} else if function == "getRange" {
+		return t.getRange(stub, args)
+	} else if function == "readPrivate" {
+		return t.readPrivate(stub, args)
 	}
 	fmt.Println("invoke did not find func: " + function) //error
 
-	// return nil for first return value, for second create an error object using the errors.New() method
-	return nil, errors.New("Received unknown function invocation: " + function)
+	return shim.Error("Received unknown function invocation: " + function)
 }
 
-// Query is called to read blocks from the blockchain
-// the method signature is the same as Init - see there for comments on the different parts
-func (t *SimpleChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-	fmt.Println("query is running " + function)
-
-	// Handle different functions
-	if function == "read" { //read a variable
-		// call the read() method on the t pointer
-		return t.read(stub, args)
-	}
-	fmt.Println("query did not find func: " + function)
-
-	return nil, errors.New("Received unknown function query")
+// WriteEvent is what we JSON-marshal and hand to stub.SetEvent below - client apps can
+// subscribe to the "writeEvent" name and get pushed one of these instead of having to
+// keep calling read() over and over to notice a change.
+type WriteEvent struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	TxID  string `json:"txId"`
 }
 
 // Perform the write of a new block to the blockchain
-// the method signature is the same as Init - see there for comments on the different parts
+// the method signature is the same as the other Invoke helpers - see Init for comments on the different parts
 // I think the name is initial lowercase to make this a private method
-func (t *SimpleChaincode) write(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+// args takes an optional 3rd element, collection: when present the write goes to
+// that private data collection via PutPrivateData instead of the shared world state,
+// and no writeEvent is emitted for it since collection members already see the write
+func (t *SimpleChaincode) write(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	// declare variables of type string
 	var name, value string
 	// declare variable of type error
 	var err error
 	fmt.Println("running write()")
 
-	if len(args) != 2 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 2. name of the variable and value to set")
+	if len(args) != 2 && len(args) != 3 {
+		return shim.Error("Incorrect number of arguments. Expecting 2 or 3. name of the variable, value to set, and an optional collection")
 	}
 	// assign new values to name, value
 	name = args[0]
 	value = args[1]
+
+	if len(args) == 3 && args[2] != "" {
+		// route storage through the named private data collection instead of the shared world state
+		err = stub.PutPrivateData(args[2], name, []byte(value))
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	}
+
 	//write the variable into the chaincode state
 	err = stub.PutState(name, []byte(value))
 	if err != nil {
-		return nil, err
+		return shim.Error(err.Error())
+	}
+
+	event := WriteEvent{Key: name, Value: value, TxID: stub.GetTxID()}
+	eventAsBytes, err := json.Marshal(event)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.SetEvent("writeEvent", eventAsBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// readPrivate is read() above but for a private data collection - args[0] is the
+// collection name this time, and args[1] is the key, same as name was in read().
+func (t *SimpleChaincode) readPrivate(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	var collection, name, jsonResp string
+	var err error
+
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting collection and name of the var to query")
+	}
+
+	collection = args[0]
+	name = args[1]
+	valAsbytes, err := stub.GetPrivateData(collection, name)
+	if err != nil {
+		jsonResp = "{\"Error\":\"Failed to get private state for " + name + "\"}"
+		return shim.Error(jsonResp)
 	}
-	return nil, nil
+
+	return shim.Success(valAsbytes)
 }
 
 // Perform the read of a block on the blockchain
-// the method signature is the same as Init - see there for comments on the different parts
-func (t *SimpleChaincode) read(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+// the method signature is the same as the other Invoke helpers - see Init for comments on the different parts
+func (t *SimpleChaincode) read(stub shim.ChaincodeStubInterface, args []string) pb.Response {
 	var name, jsonResp string
 	var err error
 
 	if len(args) != 1 {
-		return nil, errors.New("Incorrect number of arguments. Expecting name of the var to query")
+		return shim.Error("Incorrect number of arguments. Expecting name of the var to query")
 	}
 
 	name = args[0]
@@ -133,8 +237,515 @@ func (t *SimpleChaincode) read(stub shim.ChaincodeStubInterface, args []string)
 	valAsbytes, err := stub.GetState(name)
 	if err != nil {
 		jsonResp = "{\"Error\":\"Failed to get state for " + name + "\"}"
-		return nil, errors.New(jsonResp)
+		return shim.Error(jsonResp)
+	}
+
+	return shim.Success(valAsbytes)
+}
+
+// createAsset builds a new Asset from args and writes it (as JSON) under assetKey(id).
+// the method signature is the same as the other Invoke helpers - see Init for comments on the different parts
+func (t *SimpleChaincode) createAsset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 4 {
+		return shim.Error("Incorrect number of arguments. Expecting 4. id, owner, type, value")
+	}
+
+	id := args[0]
+	owner := args[1]
+	assetType := args[2]
+	value, err := strconv.Atoi(args[3])
+	if err != nil {
+		return shim.Error("Expecting integer value for asset value: " + err.Error())
+	}
+
+	existing, err := stub.GetState(assetKey(id))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if existing != nil {
+		return shim.Error("Asset already exists: " + id)
+	}
+
+	asset := Asset{ID: id, Owner: owner, Type: assetType, Value: value, UpdatedAt: time.Now().Unix()}
+	return t.putAsset(stub, asset)
+}
+
+// updateAsset looks up an existing asset and overwrites its Value field (and bumps UpdatedAt)
+// (owner is left alone, so the owner~id index doesn't need touching here)
+func (t *SimpleChaincode) updateAsset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2. id, value")
+	}
+
+	asset, response := t.getAsset(stub, args[0])
+	if response != nil {
+		return *response
+	}
+
+	value, err := strconv.Atoi(args[1])
+	if err != nil {
+		return shim.Error("Expecting integer value for asset value: " + err.Error())
+	}
+	asset.Value = value
+	asset.UpdatedAt = time.Now().Unix()
+
+	assetAsBytes, err := json.Marshal(asset)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(assetKey(asset.ID), assetAsBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(assetAsBytes)
+}
+
+// transferAsset changes who owns an asset. Because the owner is baked into the
+// composite key (see ownerIndex above), we have to delete the old owner~id key
+// and let putAsset below create the new one, otherwise the index would end up
+// pointing at the wrong owner.
+func (t *SimpleChaincode) transferAsset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2. id, newOwner")
+	}
+
+	asset, response := t.getAsset(stub, args[0])
+	if response != nil {
+		return *response
+	}
+
+	oldOwnerKey, err := stub.CreateCompositeKey(ownerIndex, []string{asset.Owner, asset.ID})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.DelState(oldOwnerKey); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	asset.Owner = args[1]
+	asset.UpdatedAt = time.Now().Unix()
+	return t.putAsset(stub, *asset)
+}
+
+// deleteAsset removes an asset. Don't forget the composite key too - if we only
+// called DelState on the asset itself the owner~id index would keep pointing at
+// a deleted asset forever.
+func (t *SimpleChaincode) deleteAsset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1. id")
+	}
+
+	asset, response := t.getAsset(stub, args[0])
+	if response != nil {
+		return *response
+	}
+
+	ownerKey, err := stub.CreateCompositeKey(ownerIndex, []string{asset.Owner, asset.ID})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.DelState(ownerKey); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.DelState(assetKey(asset.ID)); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// queryAsset is basically read() but for an asset id instead of a plain key.
+func (t *SimpleChaincode) queryAsset(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1. id")
+	}
+
+	_, response := t.getAsset(stub, args[0])
+	if response != nil {
+		return *response
+	}
+
+	assetAsBytes, err := stub.GetState(assetKey(args[0]))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(assetAsBytes)
+}
+
+// queryAssetsByOwner asks CouchDB directly for every asset whose owner field
+// matches args[0] - this only works if the peer's state database is CouchDB,
+// since GetQueryResult is a "rich query" that LevelDB doesn't understand.
+// See queryAssetsByOwnerIndex below for the LevelDB-friendly version of this.
+// We build the selector with json.Marshal instead of sprintf-ing args[0] straight
+// into the query string, otherwise a caller could put a `"` in the owner name and
+// rewrite our query to match other people's assets too.
+func (t *SimpleChaincode) queryAssetsByOwner(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1. owner")
+	}
+
+	selector := map[string]interface{}{"selector": map[string]interface{}{"owner": args[0]}}
+	queryStringAsBytes, err := json.Marshal(selector)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	resultsIterator, err := stub.GetQueryResult(string(queryStringAsBytes))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	var assets []Asset
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		var asset Asset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			return shim.Error(err.Error())
+		}
+		assets = append(assets, asset)
+	}
+
+	assetsAsBytes, err := json.Marshal(assets)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(assetsAsBytes)
+}
+
+// queryAssetsByOwnerIndex does the same job as queryAssetsByOwner above, but
+// instead of asking CouchDB to search for us, it walks the owner~id composite
+// keys we've been maintaining in createAsset/transferAsset/deleteAsset. This is
+// the part that actually lets this work on a LevelDB peer, since LevelDB has
+// no concept of a rich query - it can only look keys up by prefix.
+func (t *SimpleChaincode) queryAssetsByOwnerIndex(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1. owner")
 	}
 
-	return valAsbytes, nil
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(ownerIndex, []string{args[0]})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	var assets []Asset
+	for resultsIterator.HasNext() {
+		kvResult, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		// SplitCompositeKey undoes CreateCompositeKey - keyParts[0] is the owner we
+		// searched for and keyParts[1] is the asset id, which is all we actually need
+		_, keyParts, err := stub.SplitCompositeKey(kvResult.Key)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		id := keyParts[1]
+		asset, response := t.getAsset(stub, id)
+		if response != nil {
+			return *response
+		}
+		assets = append(assets, *asset)
+	}
+
+	assetsAsBytes, err := json.Marshal(assets)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(assetsAsBytes)
+}
+
+// putAsset is a little helper shared by createAsset and transferAsset - it writes the
+// asset itself plus its owner~id composite key, so we don't end up with the two
+// getting out of sync by only being updated in one of the callers.
+func (t *SimpleChaincode) putAsset(stub shim.ChaincodeStubInterface, asset Asset) pb.Response {
+	assetAsBytes, err := json.Marshal(asset)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(assetKey(asset.ID), assetAsBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	ownerKey, err := stub.CreateCompositeKey(ownerIndex, []string{asset.Owner, asset.ID})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	// we never actually read this value back - all we care about is that the key exists,
+	// since queryAssetsByOwnerIndex only looks at the key, not what's stored under it
+	if err := stub.PutState(ownerKey, []byte{0x00}); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(assetAsBytes)
+}
+
+// getAsset fetches an asset by id and json.Unmarshal's it back into an Asset struct.
+// This is used by every asset function above except createAsset, queryAssetsByOwner and putAsset,
+// so the "asset doesn't exist" / "couldn't unmarshal" error handling only has to live here once.
+// The *pb.Response return looks a little unusual: if it's non-nil, something went wrong and
+// the caller should just return *response straight away instead of using the Asset.
+func (t *SimpleChaincode) getAsset(stub shim.ChaincodeStubInterface, id string) (*Asset, *pb.Response) {
+	assetAsBytes, err := stub.GetState(assetKey(id))
+	if err != nil {
+		response := shim.Error(err.Error())
+		return nil, &response
+	}
+	if assetAsBytes == nil {
+		response := shim.Error("Asset not found: " + id)
+		return nil, &response
+	}
+
+	var asset Asset
+	if err := json.Unmarshal(assetAsBytes, &asset); err != nil {
+		response := shim.Error(err.Error())
+		return nil, &response
+	}
+	return &asset, nil
+}
+
+// Account is like Asset further up - a struct we JSON-marshal and store under a
+// single key (the account name), modeled on the classic example02 A/B transfer
+// chaincode you'll see in the Fabric samples.
+type Account struct {
+	Name    string `json:"name"`
+	Balance int    `json:"balance"`
+}
+
+// accountKeyPrefix gets prepended to every account name before we touch PutState/GetState
+// with it, same idea as assetKeyPrefix above - it keeps an account name from colliding
+// with an asset id or an ordinary key used by the plain write() function, since under the
+// hood they'd otherwise all be competing for the same key in the world state.
+// write() itself adds no prefix and checks for none, so a write() name that already starts
+// with "account:" or "asset:" still lands on that account or asset.
+const accountKeyPrefix = "account:"
+
+// accountKey builds the real ledger key for an account name.
+func accountKey(name string) string {
+	return accountKeyPrefix + name
+}
+
+// TransferReceipt is just what transfer() hands back so a caller can see the before/after
+// without having to call balance() twice afterwards.
+type TransferReceipt struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Amount  int    `json:"amount"`
+	FromBal int    `json:"fromBalance"`
+	ToBal   int    `json:"toBalance"`
+}
+
+// createAccount sets up a new account with a starting balance - you have to call this
+// before transfer() or balance() will do anything useful with that name.
+func (t *SimpleChaincode) createAccount(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2. name, balance")
+	}
+
+	name := args[0]
+	balance, err := strconv.Atoi(args[1])
+	if err != nil {
+		return shim.Error("Expecting integer value for balance: " + err.Error())
+	}
+
+	existing, err := stub.GetState(accountKey(name))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if existing != nil {
+		return shim.Error("Account already exists: " + name)
+	}
+
+	return t.putAccount(stub, Account{Name: name, Balance: balance})
+}
+
+// transfer moves amount out of "from" and into "to" - the amount has to be positive,
+// "from" and "to" have to be different accounts, and "from" has to actually have enough
+// balance to cover it, otherwise we bail out with shim.Error before touching anything.
+//
+// args[0] == args[1] needs its own check: since getAccount below does two separate
+// GetState/json.Unmarshal calls, "from" and "to" would end up as two independent copies
+// of the same starting balance rather than the same object, and whichever PutState runs
+// second would silently overwrite the first and mint money out of thin air.
+func (t *SimpleChaincode) transfer(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 3 {
+		return shim.Error("Incorrect number of arguments. Expecting 3. from, to, amount")
+	}
+
+	if args[0] == args[1] {
+		return shim.Error("from and to accounts must be different: " + args[0])
+	}
+
+	amount, err := strconv.Atoi(args[2])
+	if err != nil {
+		return shim.Error("Expecting integer value for amount: " + err.Error())
+	}
+	if amount <= 0 {
+		return shim.Error("Amount must be greater than zero")
+	}
+
+	from, response := t.getAccount(stub, args[0])
+	if response != nil {
+		return *response
+	}
+	to, response := t.getAccount(stub, args[1])
+	if response != nil {
+		return *response
+	}
+	if from.Balance < amount {
+		return shim.Error("Insufficient funds in account: " + from.Name)
+	}
+
+	from.Balance -= amount
+	to.Balance += amount
+
+	if response := t.putAccount(stub, *from); response.Status != shim.OK {
+		return response
+	}
+	if response := t.putAccount(stub, *to); response.Status != shim.OK {
+		return response
+	}
+
+	receipt := TransferReceipt{From: from.Name, To: to.Name, Amount: amount, FromBal: from.Balance, ToBal: to.Balance}
+	receiptAsBytes, err := json.Marshal(receipt)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(receiptAsBytes)
+}
+
+// balance is basically read() but for an account name instead of a plain key.
+func (t *SimpleChaincode) balance(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1. name")
+	}
+
+	_, response := t.getAccount(stub, args[0])
+	if response != nil {
+		return *response
+	}
+
+	accountAsBytes, err := stub.GetState(accountKey(args[0]))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(accountAsBytes)
+}
+
+// putAccount is a little helper - just json.Marshal the account and PutState it under
+// its namespaced key, shared by createAccount and transfer so we only write this once.
+func (t *SimpleChaincode) putAccount(stub shim.ChaincodeStubInterface, account Account) pb.Response {
+	accountAsBytes, err := json.Marshal(account)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(accountKey(account.Name), accountAsBytes); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(accountAsBytes)
+}
+
+// getAccount looks up an account by name and json.Unmarshal's it back into an Account.
+// Like getAsset above, the *pb.Response it returns is only non-nil when something went
+// wrong - in that case the caller should just return *response and ignore the *Account.
+func (t *SimpleChaincode) getAccount(stub shim.ChaincodeStubInterface, name string) (*Account, *pb.Response) {
+	accountAsBytes, err := stub.GetState(accountKey(name))
+	if err != nil {
+		response := shim.Error(err.Error())
+		return nil, &response
+	}
+	if accountAsBytes == nil {
+		response := shim.Error("Account not found: " + name)
+		return nil, &response
+	}
+
+	var account Account
+	if err := json.Unmarshal(accountAsBytes, &account); err != nil {
+		response := shim.Error(err.Error())
+		return nil, &response
+	}
+	return &account, nil
+}
+
+// HistoryEntry is what we turn each result from stub.GetHistoryForKey into - one
+// entry per time the key was written (or deleted), oldest first I believe.
+type HistoryEntry struct {
+	TxID      string `json:"txId"`
+	Value     string `json:"value"`
+	Timestamp int64  `json:"timestamp"`
+	IsDelete  bool   `json:"isDelete"`
+}
+
+// RangeEntry is just a key and its value, one per result from stub.GetStateByRange.
+type RangeEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// getHistory lets you see every past value a key has had - handy for auditing who
+// wrote what, since read() further up only ever shows you the current value.
+// the method signature is the same as the other Invoke helpers - see Init for comments on the different parts
+func (t *SimpleChaincode) getHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting 1. key")
+	}
+
+	// GetHistoryForKey gives us back an iterator rather than a slice, presumably
+	// because a key's history could be arbitrarily long - have to call Close() on
+	// it ourselves when we're done, hence the defer
+	resultsIterator, err := stub.GetHistoryForKey(args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	var history []HistoryEntry
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		history = append(history, HistoryEntry{
+			TxID:      modification.TxId,
+			Value:     string(modification.Value),
+			Timestamp: modification.Timestamp.GetSeconds(),
+			IsDelete:  modification.IsDelete,
+		})
+	}
+
+	historyAsBytes, err := json.Marshal(history)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(historyAsBytes)
+}
+
+// getRange lists every key/value pair between startKey and endKey, so you don't have
+// to already know every key someone's written via write() in order to list them.
+func (t *SimpleChaincode) getRange(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 2. startKey, endKey")
+	}
+
+	resultsIterator, err := stub.GetStateByRange(args[0], args[1])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	var entries []RangeEntry
+	for resultsIterator.HasNext() {
+		kvResult, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		entries = append(entries, RangeEntry{Key: kvResult.Key, Value: string(kvResult.Value)})
+	}
+
+	entriesAsBytes, err := json.Marshal(entries)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(entriesAsBytes)
 }